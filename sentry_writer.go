@@ -103,8 +103,13 @@ Also see `example/main.go`.
 package sentrywriter
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -114,6 +119,7 @@ import (
 // SentryClient is an interface which represents the sentry-go package client.
 type SentryClient interface {
 	CaptureMessage(message string, hint *sentry.EventHint, scope sentry.EventModifier) *sentry.EventID
+	CaptureEvent(event *sentry.Event, hint *sentry.EventHint, scope sentry.EventModifier) *sentry.EventID
 	Flush(timeout time.Duration) bool
 }
 
@@ -129,12 +135,118 @@ type LogLevel struct {
 	SentryLevel    sentry.Level
 }
 
+// DropPolicy controls what WithAsync does when the async queue is full.
+type DropPolicy int
+
+const (
+	// DropPolicyBlock makes Write block until there is room on the async
+	// queue. This is the default (zero-value) policy.
+	DropPolicyBlock DropPolicy = iota
+	// DropPolicyDropOldest discards the oldest queued event to make room
+	// for the new one.
+	DropPolicyDropOldest
+	// DropPolicyDropNewest discards the event that was about to be
+	// enqueued, leaving the queue unchanged.
+	DropPolicyDropNewest
+)
+
+// Stats reports counters for a writer's async pipeline. See WithAsync and
+// the SentryWriter.Stats method.
+type Stats struct {
+	Enqueued int64
+	Sent     int64
+	Dropped  int64
+}
+
+// asyncEvent is what gets queued by Write when async mode is enabled. The
+// scope is captured at enqueue time (it is already a clone, see getScope),
+// so breadcrumb snapshotting happens before the event sits in the queue,
+// not when a worker eventually dequeues it.
+type asyncEvent struct {
+	message string
+	event   *sentry.Event
+	scope   *sentry.Scope
+}
+
+// asyncPipeline is the bounded queue and worker pool set up by WithAsync. It
+// is shared by every SentryWriter derived (e.g. via WithContext) from the
+// writer that WithAsync was called on, since it belongs to the underlying
+// Sentry client rather than to any one scope.
+type asyncPipeline struct {
+	queue      chan asyncEvent
+	dropPolicy DropPolicy
+	enqueued   int64
+	sent       int64
+	dropped    int64
+}
+
+func (p *asyncPipeline) enqueue(event asyncEvent) {
+	switch p.dropPolicy {
+	case DropPolicyDropNewest:
+		select {
+		case p.queue <- event:
+			atomic.AddInt64(&p.enqueued, 1)
+		default:
+			atomic.AddInt64(&p.dropped, 1)
+		}
+	case DropPolicyDropOldest:
+		for {
+			select {
+			case p.queue <- event:
+				atomic.AddInt64(&p.enqueued, 1)
+				return
+			default:
+				select {
+				case <-p.queue:
+					atomic.AddInt64(&p.dropped, 1)
+				default:
+				}
+			}
+		}
+	default: // DropPolicyBlock
+		p.queue <- event
+		atomic.AddInt64(&p.enqueued, 1)
+	}
+}
+
+func (p *asyncPipeline) pending() int64 {
+	pending := atomic.LoadInt64(&p.enqueued) - atomic.LoadInt64(&p.sent)
+	if p.dropPolicy == DropPolicyDropOldest {
+		// only DropPolicyDropOldest drops events that were already enqueued;
+		// DropPolicyDropNewest's drops never reached the queue
+		pending -= atomic.LoadInt64(&p.dropped)
+	}
+	return pending
+}
+
+// drain blocks until the queue has been fully processed or timeout elapses,
+// returning false in the latter case.
+func (p *asyncPipeline) drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for p.pending() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func (p *asyncPipeline) stats() Stats {
+	return Stats{
+		Enqueued: atomic.LoadInt64(&p.enqueued),
+		Sent:     atomic.LoadInt64(&p.sent),
+		Dropped:  atomic.LoadInt64(&p.dropped),
+	}
+}
+
 // SentryWriter implements the io.Writer interface. It is a wrapper over the
 // sentry-go client and sends the supplied logs of the specified log level to
 // Sentry. It assumes that the logs are json encoded. Writes are asynchronous,
 // so remember to call Flush before exiting the program.
 type SentryWriter struct {
 	mu                 sync.RWMutex
+	ctx                context.Context
 	client             SentryClient
 	scope              *sentry.Scope
 	logLevels          []LogLevel
@@ -142,6 +254,109 @@ type SentryWriter struct {
 	addBreadcrumbsFlag bool
 	breadcrumbsLimit   int
 	levelFieldName     string
+	dynamicTagFields   []string
+	staticTags         map[string]string
+	async              *asyncPipeline
+	stackTraceField    string
+	errorField         string
+	beforeSend         func(*sentry.Event) *sentry.Event
+	breadcrumbMapping  BreadcrumbFieldMapping
+}
+
+// BreadcrumbFieldMapping configures which JSON field names in a log get
+// mapped onto which structured sentry.Breadcrumb fields, rather than being
+// dumped into the breadcrumb's opaque Data map. The zero value is not
+// usable directly; see DefaultBreadcrumbFieldMapping for the convention
+// used by default.
+type BreadcrumbFieldMapping struct {
+	// CategoryField is lifted into the breadcrumb's Category.
+	CategoryField string
+	// TypeField is lifted into the breadcrumb's Type.
+	TypeField string
+	// MessageFields are, in order, checked for the breadcrumb's Message.
+	MessageFields []string
+	// TimeFields are, in order, checked for the breadcrumb's Timestamp.
+	// Values may be RFC3339 strings or unix timestamps (seconds, as a
+	// number or a numeric string).
+	TimeFields []string
+}
+
+// DefaultBreadcrumbFieldMapping is the field mapping used unless
+// WithBreadcrumbFieldMapping is called. It follows the convention used by
+// pace/bricks for postgres/redis breadcrumbs: `sentry:category`,
+// `sentry:type`, `message`/`msg` and `time`/`timestamp`.
+func DefaultBreadcrumbFieldMapping() BreadcrumbFieldMapping {
+	return BreadcrumbFieldMapping{
+		CategoryField: "sentry:category",
+		TypeField:     "sentry:type",
+		MessageFields: []string{"message", "msg"},
+		TimeFields:    []string{"time", "timestamp"},
+	}
+}
+
+// writerContextKey is the key used to store a *SentryWriter on a
+// context.Context by WithContext.
+type writerContextKey struct{}
+
+// sinkContextKey is the key used to store a *Sink on a context.Context by
+// NewSinkContext.
+type sinkContextKey struct{}
+
+// Sink buffers logs that didn't match a configured LogLevel, keyed to a
+// single context.Context, so that they can be flushed as breadcrumbs onto
+// whichever event is eventually sent to Sentry on that same context. Unlike
+// the breadcrumbs accumulated directly on a SentryWriter's shared scope, a
+// Sink's buffer can never bleed into an unrelated request: see
+// NewSinkContext. entries is a ring buffer bounded by the limit passed to
+// add, so a long-lived context that never sees a matching log (or sees many
+// non-matching logs before one) can't grow it without bound.
+type Sink struct {
+	mu      sync.Mutex
+	entries [][]byte
+}
+
+// add appends log to the Sink, evicting the oldest buffered entry once
+// limit is exceeded. limit <= 0 means unbounded.
+func (sink *Sink) add(log []byte, limit int) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	entry := make([]byte, len(log))
+	copy(entry, log)
+	sink.entries = append(sink.entries, entry)
+
+	if limit > 0 && len(sink.entries) > limit {
+		sink.entries = sink.entries[len(sink.entries)-limit:]
+	}
+}
+
+// drain returns the buffered logs and empties the Sink.
+func (sink *Sink) drain() [][]byte {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	entries := sink.entries
+	sink.entries = nil
+	return entries
+}
+
+// NewSinkContext returns a context derived from ctx with a breadcrumb Sink
+// attached. Pass the returned context to SentryWriter.WithContext (and use
+// the resulting writer's Context method to thread it through your request)
+// so that Write buffers would-be breadcrumbs on this Sink instead of on the
+// writer's shared scope. For example:
+//     ctx := sentrywriter.NewSinkContext(r.Context())
+//     requestWriter := sentryWriter.WithContext(ctx)
+func NewSinkContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sinkContextKey{}, &Sink{})
+}
+
+func sinkFromContext(ctx context.Context) (*Sink, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	sink, ok := ctx.Value(sinkContextKey{}).(*Sink)
+	return sink, ok
 }
 
 // New returns a pointer to the SentryWriter, with the specified log levels set.
@@ -153,9 +368,10 @@ func New(logLevels ...LogLevel) *SentryWriter {
 
 	// The sentry-go package
 	writer := SentryWriter{
-		levelFieldName: "level",
-		scope:          sentry.NewScope(),
-		logLevels:      logLevels,
+		levelFieldName:    "level",
+		scope:             sentry.NewScope(),
+		logLevels:         logLevels,
+		breadcrumbMapping: DefaultBreadcrumbFieldMapping(),
 	}
 	if len(logLevels) > 0 {
 		writer.turnOnFilterLogsFlag()
@@ -237,6 +453,299 @@ func (s *SentryWriter) WithUserID(userID string) *SentryWriter {
 	return s
 }
 
+// WithContext returns a new SentryWriter which shares the same underlying
+// Sentry client as s, but has its own isolated sentry.Scope cloned from s's
+// scope. This lets you attach request-scoped tags, user info, extras and
+// breadcrumbs (via WithTag, WithUserID, WithExtras, WithFingerprint and
+// WithBreadcrumbs) to a single request without those details leaking into
+// other concurrent requests that share the same parent writer. The returned
+// writer is bound onto ctx, so that it can be retrieved further down the
+// call stack with WriterFromContext; use the returned writer's Context
+// method to obtain that derived context. For example:
+//     ctx, writer := ctx, sentryWriter.WithContext(ctx).WithTag("requestID", requestID)
+//     ctx = writer.Context()
+func (s *SentryWriter) WithContext(ctx context.Context) *SentryWriter {
+	child := s.clone()
+	child.ctx = context.WithValue(ctx, writerContextKey{}, child)
+	return child
+}
+
+// Context returns the context.Context that this writer is bound to, i.e.
+// the context that WriterFromContext will find this writer on. It is nil
+// unless the writer was obtained via WithContext.
+func (s *SentryWriter) Context() context.Context {
+	return s.ctx
+}
+
+// WriterFromContext retrieves the *SentryWriter previously bound to ctx by
+// WithContext. It returns false if no writer was bound to ctx.
+func WriterFromContext(ctx context.Context) (*SentryWriter, bool) {
+	writer, ok := ctx.Value(writerContextKey{}).(*SentryWriter)
+	return writer, ok
+}
+
+// clone returns a new SentryWriter sharing the same client and
+// configuration as s, but with its own copy of the mutable scope and log
+// levels, so that it can evolve independently of s.
+func (s *SentryWriter) clone() *SentryWriter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logLevels := make([]LogLevel, len(s.logLevels))
+	copy(logLevels, s.logLevels)
+
+	dynamicTagFields := make([]string, len(s.dynamicTagFields))
+	copy(dynamicTagFields, s.dynamicTagFields)
+
+	staticTags := make(map[string]string, len(s.staticTags))
+	for k, v := range s.staticTags {
+		staticTags[k] = v
+	}
+
+	return &SentryWriter{
+		ctx:                s.ctx,
+		client:             s.client,
+		scope:              s.scope.Clone(),
+		logLevels:          logLevels,
+		filterLogsFlag:     s.filterLogsFlag,
+		addBreadcrumbsFlag: s.addBreadcrumbsFlag,
+		breadcrumbsLimit:   s.breadcrumbsLimit,
+		levelFieldName:     s.levelFieldName,
+		dynamicTagFields:   dynamicTagFields,
+		staticTags:         staticTags,
+		async:              s.async,
+		stackTraceField:    s.stackTraceField,
+		errorField:         s.errorField,
+		beforeSend:         s.beforeSend,
+		breadcrumbMapping:  s.breadcrumbMapping,
+	}
+}
+
+// WithBreadcrumbFieldMapping overrides the default field mapping (see
+// DefaultBreadcrumbFieldMapping) used when building a breadcrumb out of a
+// filtered-out log, for example if your logger uses different key names.
+// For example:
+//     writer := sentrywriter.New().WithBreadcrumbFieldMapping(sentrywriter.BreadcrumbFieldMapping{
+//     	CategoryField: "category",
+//     	TypeField:     "type",
+//     	MessageFields: []string{"msg"},
+//     	TimeFields:    []string{"ts"},
+//     })
+func (s *SentryWriter) WithBreadcrumbFieldMapping(mapping BreadcrumbFieldMapping) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breadcrumbMapping = mapping
+	return s
+}
+
+func (s *SentryWriter) getBreadcrumbFieldMapping() BreadcrumbFieldMapping {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.breadcrumbMapping
+}
+
+// WithStackTraceField configures the name of the JSON field that holds a
+// stack trace, such as the one zerolog's pkgerrors integration produces via
+// `.Stack()` (a `[]map[string]string` of func/source/line entries). When
+// this field is present on a log passed to Write, the event sent to Sentry
+// is upgraded from a plain CaptureMessage into a full sentry.Event carrying
+// an Exception with a parsed Stacktrace, which Sentry uses for grouping.
+// For example:
+//     writer := sentrywriter.New().WithStackTraceField("stack")
+func (s *SentryWriter) WithStackTraceField(name string) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stackTraceField = name
+	return s
+}
+
+// WithExceptionExtraction configures the name of the JSON field that holds
+// an error message, such as the one zerolog's `.Err(err)` produces. When
+// this field is present on a log passed to Write, the event sent to Sentry
+// is upgraded from a plain CaptureMessage into a full sentry.Event carrying
+// an Exception built from the error message (and, if WithStackTraceField is
+// also configured, the parsed stack trace). For example:
+//     writer := sentrywriter.New().WithExceptionExtraction("error")
+func (s *SentryWriter) WithExceptionExtraction(errorField string) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errorField = errorField
+	return s
+}
+
+// WithBeforeSend registers a hook that is given the chance to inspect or
+// mutate a sentry.Event built by WithStackTraceField/WithExceptionExtraction
+// before it is dispatched. Returning nil from the hook discards the event
+// entirely. It has no effect on plain CaptureMessage events. For example:
+//     writer := sentrywriter.New().WithExceptionExtraction("error").WithBeforeSend(scrubSecrets)
+func (s *SentryWriter) WithBeforeSend(hook func(*sentry.Event) *sentry.Event) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.beforeSend = hook
+	return s
+}
+
+func (s *SentryWriter) getExceptionFields() (errorField, stackTraceField string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.errorField, s.stackTraceField
+}
+
+func (s *SentryWriter) hasExceptionExtraction() bool {
+	errorField, stackTraceField := s.getExceptionFields()
+	return errorField != "" || stackTraceField != ""
+}
+
+func (s *SentryWriter) getBeforeSend() func(*sentry.Event) *sentry.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.beforeSend
+}
+
+// WithAsync decouples Write from the underlying Sentry client: instead of
+// calling client.CaptureMessage directly, events are enqueued onto a bounded
+// queue of size bufferSize and sent by workers background goroutines. This
+// stops a network hiccup talking to Sentry from stalling the caller's
+// goroutine on every Write. dropPolicy controls what happens when the queue
+// is full; see DropPolicy. Call Stats to inspect the queue's counters. For
+// example:
+//     writer := sentrywriter.New().WithAsync(100, 4, sentrywriter.DropPolicyDropOldest)
+func (s *SentryWriter) WithAsync(bufferSize int, workers int, dropPolicy DropPolicy) *SentryWriter {
+	s.mu.Lock()
+	pipeline := &asyncPipeline{
+		queue:      make(chan asyncEvent, bufferSize),
+		dropPolicy: dropPolicy,
+	}
+	s.async = pipeline
+	s.mu.Unlock()
+
+	for i := 0; i < workers; i++ {
+		go s.runAsyncWorker(pipeline)
+	}
+
+	return s
+}
+
+func (s *SentryWriter) runAsyncWorker(pipeline *asyncPipeline) {
+	for job := range pipeline.queue {
+		s.send(job)
+		atomic.AddInt64(&pipeline.sent, 1)
+	}
+}
+
+// Stats returns the current counters for this writer's async pipeline. It
+// returns a zero Stats if WithAsync has not been called.
+func (s *SentryWriter) Stats() Stats {
+	pipeline := s.getAsyncPipeline()
+	if pipeline == nil {
+		return Stats{}
+	}
+	return pipeline.stats()
+}
+
+func (s *SentryWriter) getAsyncPipeline() *asyncPipeline {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.async
+}
+
+// dispatch sends message to Sentry as a plain CaptureMessage, either
+// directly or, if WithAsync has been called, via the async pipeline.
+func (s *SentryWriter) dispatch(message string, scope *sentry.Scope) {
+	s.enqueueOrSend(asyncEvent{message: message, scope: scope})
+}
+
+// dispatchEvent sends event to Sentry via CaptureEvent, either directly or,
+// if WithAsync has been called, via the async pipeline.
+func (s *SentryWriter) dispatchEvent(event *sentry.Event, scope *sentry.Scope) {
+	s.enqueueOrSend(asyncEvent{event: event, scope: scope})
+}
+
+func (s *SentryWriter) enqueueOrSend(job asyncEvent) {
+	pipeline := s.getAsyncPipeline()
+	if pipeline == nil {
+		s.send(job)
+		return
+	}
+
+	pipeline.enqueue(job)
+}
+
+func (s *SentryWriter) send(job asyncEvent) {
+	if job.event != nil {
+		s.client.CaptureEvent(job.event, nil, job.scope)
+		return
+	}
+	s.client.CaptureMessage(job.message, nil, job.scope)
+}
+
+// WithDynamicTags configures field names which, when present in a
+// json-formatted log written via Write, are lifted out of the log and
+// attached as tags on the outgoing Sentry event. This lets you pivot Sentry
+// issues by, for example, trace ID or tenant without wrapping every log
+// call. For example:
+//     writer := sentrywriter.New().WithDynamicTags("traceID", "entityName")
+func (s *SentryWriter) WithDynamicTags(fieldNames ...string) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dynamicTagFields = append(s.dynamicTagFields, fieldNames...)
+	return s
+}
+
+// WithStaticTags sets tags which are attached to every outgoing Sentry
+// event, regardless of the contents of the log. For example:
+//     writer := sentrywriter.New().WithStaticTags(map[string]string{"service": "billing"})
+func (s *SentryWriter) WithStaticTags(tags map[string]string) *SentryWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.staticTags == nil {
+		s.staticTags = make(map[string]string, len(tags))
+	}
+	for key, value := range tags {
+		s.staticTags[key] = value
+	}
+	return s
+}
+
+// WithTag sets a tag on this writer's scope that will be reported alongside
+// every Sentry event sent through it. For example:
+//     writer := sentrywriter.New().WithTag("region", "eu-west-1")
+func (s *SentryWriter) WithTag(key, value string) *SentryWriter {
+	// scope has its own mutex
+	s.scope.SetTag(key, value)
+	return s
+}
+
+// WithExtras sets extra context that will be reported alongside every
+// Sentry event sent through this writer. For example:
+//     writer := sentrywriter.New().WithExtras(map[string]interface{}{"retries": 3})
+func (s *SentryWriter) WithExtras(extras map[string]interface{}) *SentryWriter {
+	// scope has its own mutex
+	s.scope.SetExtras(extras)
+	return s
+}
+
+// WithFingerprint overrides the Sentry grouping fingerprint for every event
+// sent through this writer. See
+// https://docs.sentry.io/platform-redirect/?next=/data-management/event-grouping/sdk-fingerprinting/
+// for details on how fingerprints affect issue grouping. For example:
+//     writer := sentrywriter.New().WithFingerprint("database", "timeout")
+func (s *SentryWriter) WithFingerprint(fingerprint ...string) *SentryWriter {
+	// scope has its own mutex
+	s.scope.SetFingerprint(fingerprint)
+	return s
+}
+
 // WithClient allows you to substitute the client that is being used, rather
 // than the default client from the sentry-go package. For example:
 //     writer := sentrywriter.New().WithClient(client)
@@ -265,7 +774,11 @@ func (s *SentryWriter) WithBreadcrumbs(limit int) *SentryWriter {
 }
 
 // Write is the implementation of the io.Writer interface. It checks if the log
-// is at one of the preset log levels and if so it writes it to Sentry.
+// is at one of the preset log levels and if so it writes it to Sentry. The
+// effective scope is always s's own scope: a writer returned by WithContext
+// carries its own isolated scope, so fetching the request-scoped writer via
+// WriterFromContext (rather than using the original shared writer) is how
+// you pick up request-scoped tags, user info and breadcrumbs.
 func (s *SentryWriter) Write(log []byte) (int, error) {
 	if s.client == nil {
 		return 0, errors.New("no Sentry client supplied")
@@ -273,11 +786,14 @@ func (s *SentryWriter) Write(log []byte) (int, error) {
 
 	scope := s.getScope()
 
-	if s.shouldFilterLogs() {
-		var eventMap map[string]json.RawMessage
+	var eventMap map[string]json.RawMessage
+	if s.shouldFilterLogs() || s.hasDynamicTags() || s.hasExceptionExtraction() {
 		if err := json.Unmarshal(log, &eventMap); err != nil {
 			return 0, errors.Wrap(err, "json.Unmarshal log")
 		}
+	}
+
+	if s.shouldFilterLogs() {
 		var level string
 		if err := json.Unmarshal(eventMap[s.getLevelFieldName()], &level); err != nil {
 			return 0, errors.Wrapf(err, `json.Unmarshal eventMap["%s"]`, s.getLevelFieldName())
@@ -292,34 +808,370 @@ func (s *SentryWriter) Write(log []byte) (int, error) {
 		scope.SetLevel(logLevel.SentryLevel)
 	}
 
-	s.client.CaptureMessage(string(log), nil, scope)
-	// as we have captured the message, we must now clear the breadcrumbs
+	s.applyBreadcrumbs(scope)
+	s.applyTags(scope, eventMap)
+
+	if s.hasExceptionExtraction() {
+		if event := s.buildExceptionEvent(log, eventMap); event != nil {
+			if hook := s.getBeforeSend(); hook != nil {
+				event = hook(event)
+			}
+			if event != nil {
+				s.dispatchEvent(event, scope)
+			}
+			// the breadcrumbs have already been snapshotted onto scope
+			// above, so it is safe to clear them now even though dispatch
+			// may only have enqueued the event for asynchronous sending
+			s.clearBreadcrumbs()
+			return len(log), nil
+		}
+	}
+
+	s.dispatch(string(log), scope)
+	// the breadcrumbs have already been snapshotted onto scope above, so it
+	// is safe to clear them now even though dispatch may only have enqueued
+	// the event for asynchronous sending
 	s.clearBreadcrumbs()
 
 	return len(log), nil
 }
 
+// buildExceptionEvent builds a sentry.Event with a populated Exception (and,
+// if a stack trace field is configured and present, a parsed Stacktrace)
+// from the configured error/stack-trace fields in eventMap. It returns nil
+// if neither field yields anything usable, in which case Write falls back
+// to the plain CaptureMessage path. The scope is intentionally not applied
+// here: dispatchEvent hands the same scope to the client's CaptureEvent,
+// which applies it once on our behalf, exactly as the plain CaptureMessage
+// path already relies on.
+func (s *SentryWriter) buildExceptionEvent(log []byte, eventMap map[string]json.RawMessage) *sentry.Event {
+	errorField, stackTraceField := s.getExceptionFields()
+
+	var errMessage string
+	if errorField != "" {
+		if raw, found := eventMap[errorField]; found {
+			if value, ok := coerceToString(raw); ok {
+				errMessage = value
+			}
+		}
+	}
+
+	var frames []sentry.Frame
+	if stackTraceField != "" {
+		if raw, found := eventMap[stackTraceField]; found {
+			frames = parseStackTrace(raw)
+		}
+	}
+
+	if errMessage == "" && len(frames) == 0 {
+		return nil
+	}
+	if errMessage == "" {
+		errMessage = string(log)
+	}
+
+	exception := sentry.Exception{Value: errMessage}
+	if len(frames) > 0 {
+		exception.Stacktrace = &sentry.Stacktrace{Frames: frames}
+	}
+
+	event := sentry.NewEvent()
+	event.Message = string(log)
+	event.Exception = []sentry.Exception{exception}
+
+	return event
+}
+
+// stackFrame is the shape zerolog's pkgerrors integration marshals each
+// stack trace entry into, via `[]map[string]string`.
+type stackFrame struct {
+	Func   string `json:"func"`
+	Line   string `json:"line"`
+	Source string `json:"source"`
+}
+
+// parseStackTrace parses a stack trace field, which may be either a
+// zerolog-style `[]map[string]string` of func/source/line entries, or a
+// plain pkg/errors stack trace string. The latter must be the output of
+// formatting the error's errors.StackTrace with %+v, e.g.
+// `fmt.Sprintf("%+v", err.(interface{ StackTrace() errors.StackTrace
+// }).StackTrace())` — NOT `fmt.Sprintf("%+v", err)`, which prints the
+// error's message ahead of the frames and misaligns every pair below.
+// It returns frames in Sentry's expected oldest-first order.
+func parseStackTrace(raw json.RawMessage) []sentry.Frame {
+	var structured []stackFrame
+	if err := json.Unmarshal(raw, &structured); err == nil {
+		frames := make([]sentry.Frame, 0, len(structured))
+		for i := len(structured) - 1; i >= 0; i-- {
+			entry := structured[i]
+			line, _ := strconv.Atoi(entry.Line)
+			frames = append(frames, sentry.Frame{
+				Function: entry.Func,
+				Filename: entry.Source,
+				Lineno:   line,
+			})
+		}
+		return frames
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return parsePkgErrorsStack(text)
+	}
+
+	return nil
+}
+
+// parsePkgErrorsStack parses a formatted errors.StackTrace (see
+// parseStackTrace): an alternating sequence of function-name lines and
+// tab-indented "file:line" location lines, with no leading message line.
+func parsePkgErrorsStack(trace string) []sentry.Frame {
+	lines := strings.Split(strings.TrimSpace(trace), "\n")
+
+	var frames []sentry.Frame
+	for i := 0; i+1 < len(lines); i += 2 {
+		function := strings.TrimSpace(lines[i])
+		location := strings.TrimSpace(lines[i+1])
+
+		idx := strings.LastIndex(location, ":")
+		if idx < 0 {
+			continue
+		}
+		file := location[:idx]
+		line, _ := strconv.Atoi(location[idx+1:])
+
+		frames = append(frames, sentry.Frame{
+			Function: function,
+			Filename: file,
+			Lineno:   line,
+		})
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+
+	return frames
+}
+
+// applyTags sets the configured static tags, plus any configured dynamic
+// tag fields found in eventMap, onto scope. scope is always a fresh clone
+// (see getScope), so tags set here never bleed into another Write call.
+func (s *SentryWriter) applyTags(scope *sentry.Scope, eventMap map[string]json.RawMessage) {
+	s.mu.RLock()
+	staticTags := make(map[string]string, len(s.staticTags))
+	for key, value := range s.staticTags {
+		staticTags[key] = value
+	}
+	dynamicTagFields := make([]string, len(s.dynamicTagFields))
+	copy(dynamicTagFields, s.dynamicTagFields)
+	s.mu.RUnlock()
+
+	for key, value := range staticTags {
+		scope.SetTag(key, value)
+	}
+
+	for _, field := range dynamicTagFields {
+		raw, found := eventMap[field]
+		if !found {
+			continue
+		}
+		if value, ok := coerceToString(raw); ok {
+			scope.SetTag(field, value)
+		}
+	}
+}
+
+// coerceToString turns a json.RawMessage into a string suitable for use as
+// a Sentry tag value. It returns false if raw is JSON null.
+func coerceToString(raw json.RawMessage) (string, bool) {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str, true
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+func (s *SentryWriter) hasDynamicTags() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.dynamicTagFields) > 0
+}
+
+// addBreadcrumb stores a log that didn't match a configured LogLevel so
+// that it can later be attached as a breadcrumb to an event that does
+// match. If a Sink is attached to s's context (see NewSinkContext), the log
+// is buffered there, keyed to that context, instead of on the shared scope;
+// this is what stops a breadcrumb from one request bleeding into another
+// request's error when they share a SentryWriter.
 func (s *SentryWriter) addBreadcrumb(log []byte) {
 	if !s.shouldAddBreadcrumb() {
 		return
 	}
 
+	if sink, found := sinkFromContext(s.ctx); found {
+		sink.add(log, s.getBreadcrumbsLimit())
+		return
+	}
+
+	s.addBreadcrumbToScope(s.buildBreadcrumb(log))
+}
+
+// applyBreadcrumbs flushes any logs buffered on a context-attached Sink onto
+// scope as breadcrumbs, honouring the writer's breadcrumb limit. It is a
+// no-op if no Sink is attached to s's context, in which case the
+// already-accumulated breadcrumbs on s.scope (added via addBreadcrumbToScope
+// as logs came in) are carried over by getScope's clone as before.
+func (s *SentryWriter) applyBreadcrumbs(scope *sentry.Scope) {
+	sink, found := sinkFromContext(s.ctx)
+	if !found {
+		return
+	}
+
+	limit := s.getBreadcrumbsLimit()
+	for _, log := range sink.drain() {
+		scope.AddBreadcrumb(s.buildBreadcrumb(log), limit)
+	}
+}
+
+// buildBreadcrumb turns a raw log into a sentry.Breadcrumb. Fields
+// recognized by the writer's BreadcrumbFieldMapping (see
+// WithBreadcrumbFieldMapping) are lifted onto the breadcrumb's structured
+// Category, Type, Message and Timestamp fields instead of being dumped into
+// the opaque Data map; the configured log level field is mapped onto Level
+// via breadcrumbLevelFromString. This is deliberately a separate,
+// non-filtering table from the writer's own LogLevels: buildBreadcrumb is
+// only ever called for a log whose level did *not* match one of those (see
+// addBreadcrumb/applyBreadcrumbs), so looking Level up in that same table
+// could never succeed. Everything else ends up in Data. If the log isn't
+// json, it falls back to setting Message to the raw log.
+func (s *SentryWriter) buildBreadcrumb(log []byte) *sentry.Breadcrumb {
 	breadcrumb := sentry.Breadcrumb{
 		Timestamp: time.Now().UTC(),
 	}
 
-	var dataMap map[string]interface{}
-	if err := json.Unmarshal(log, &dataMap); err != nil {
+	var eventMap map[string]json.RawMessage
+	if err := json.Unmarshal(log, &eventMap); err != nil {
 		// i.e. we can't unmarshal it, which is unexpected as these
 		// should really be json logs, but we can just set the Message
 		// field to be the raw log.
 		breadcrumb.Message = string(log)
-		s.addBreadcrumbToScope(&breadcrumb)
-		return
+		return &breadcrumb
+	}
+
+	mapping := s.getBreadcrumbFieldMapping()
+	levelFieldName := s.getLevelFieldName()
+	data := make(map[string]interface{}, len(eventMap))
+
+	for field, raw := range eventMap {
+		switch {
+		case field == mapping.CategoryField:
+			if value, ok := coerceToString(raw); ok {
+				breadcrumb.Category = value
+			}
+		case field == mapping.TypeField:
+			if value, ok := coerceToString(raw); ok {
+				breadcrumb.Type = value
+			}
+		case containsString(mapping.MessageFields, field):
+			if value, ok := coerceToString(raw); ok {
+				breadcrumb.Message = value
+			}
+		case containsString(mapping.TimeFields, field):
+			if timestamp, ok := parseBreadcrumbTimestamp(raw); ok {
+				breadcrumb.Timestamp = timestamp
+			}
+		case field == levelFieldName:
+			if value, ok := coerceToString(raw); ok {
+				if level, found := breadcrumbLevelFromString(value); found {
+					breadcrumb.Level = level
+				}
+			}
+		default:
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err == nil {
+				data[field] = value
+			}
+		}
+	}
+
+	if len(data) > 0 {
+		breadcrumb.Data = data
+	}
+
+	return &breadcrumb
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// breadcrumbLevels maps common logging level names (including zerolog's own
+// "warn"/"panic") onto sentry.Level, independent of any writer's configured
+// LogLevels, for annotating a breadcrumb's Level.
+var breadcrumbLevels = map[string]sentry.Level{
+	"debug":   sentry.LevelDebug,
+	"info":    sentry.LevelInfo,
+	"warning": sentry.LevelWarning,
+	"warn":    sentry.LevelWarning,
+	"error":   sentry.LevelError,
+	"fatal":   sentry.LevelFatal,
+	"panic":   sentry.LevelFatal,
+}
+
+func breadcrumbLevelFromString(value string) (sentry.Level, bool) {
+	level, found := breadcrumbLevels[strings.ToLower(value)]
+	return level, found
+}
+
+// parseBreadcrumbTimestamp parses a breadcrumb timestamp field, which may
+// be an RFC3339 string, a unix timestamp (seconds) encoded as a number, or
+// a unix timestamp encoded as a numeric string.
+func parseBreadcrumbTimestamp(raw json.RawMessage) (time.Time, bool) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t, true
+		}
+		if seconds, err := strconv.ParseFloat(text, 64); err == nil {
+			return unixSecondsToTime(seconds), true
+		}
+		return time.Time{}, false
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil {
+		return unixSecondsToTime(seconds), true
 	}
 
-	breadcrumb.Data = dataMap
-	s.addBreadcrumbToScope(&breadcrumb)
+	return time.Time{}, false
+}
+
+func unixSecondsToTime(seconds float64) time.Time {
+	whole := int64(seconds)
+	nanos := int64((seconds - float64(whole)) * float64(time.Second))
+	return time.Unix(whole, nanos).UTC()
 }
 
 func (s *SentryWriter) shouldAddBreadcrumb() bool {
@@ -367,9 +1219,16 @@ func (s *SentryWriter) findMatchingLogLevel(level string) (LogLevel, bool) {
 // before exiting your program. The provided timeout is the maximum length of
 // time to block until all the logs have been sent to Sentry. It returns false
 // if the timeout is reached, which may signify that not all messages were sent
-// to Sentry.
+// to Sentry. If WithAsync is in use, Flush first waits (up to timeout) for the
+// async queue to drain before flushing the underlying client.
 func (s *SentryWriter) Flush(timeout time.Duration) bool {
-	return s.client.Flush(timeout)
+	deadline := time.Now().Add(timeout)
+	if pipeline := s.getAsyncPipeline(); pipeline != nil {
+		if !pipeline.drain(timeout) {
+			return false
+		}
+	}
+	return s.client.Flush(time.Until(deadline))
 }
 
 func (s *SentryWriter) shouldFilterLogs() bool {
@@ -1,18 +1,25 @@
 package sentrywriter_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/mec07/sentrywriter"
+	"github.com/pkg/errors"
 	"gotest.tools/assert"
 )
 
 type mockClient struct {
 	sync.Mutex
 	messages []string
+	scopes   []sentry.EventModifier
+	events   []*sentry.Event
 }
 
 func (m *mockClient) Flush(timeout time.Duration) bool {
@@ -24,9 +31,44 @@ func (m *mockClient) CaptureMessage(message string, hint *sentry.EventHint, scop
 	defer m.Unlock()
 
 	m.messages = append(m.messages, message)
+	m.scopes = append(m.scopes, scope)
 	return &sentry.NewEvent().EventID
 }
 
+func (m *mockClient) CaptureEvent(event *sentry.Event, hint *sentry.EventHint, scope sentry.EventModifier) *sentry.EventID {
+	m.Lock()
+	defer m.Unlock()
+
+	m.events = append(m.events, event)
+	m.scopes = append(m.scopes, scope)
+	return &event.EventID
+}
+
+func (m *mockClient) getEvents() []*sentry.Event {
+	m.Lock()
+	defer m.Unlock()
+
+	events := make([]*sentry.Event, len(m.events))
+	copy(events, m.events)
+	return events
+}
+
+func (m *mockClient) getScopes() []sentry.EventModifier {
+	m.Lock()
+	defer m.Unlock()
+
+	scopes := make([]sentry.EventModifier, len(m.scopes))
+	copy(scopes, m.scopes)
+	return scopes
+}
+
+// tagsFromScope applies a scope to a fresh event purely to read back the
+// tags that were set on it, for test assertions.
+func tagsFromScope(scope sentry.EventModifier) map[string]string {
+	event := sentry.NewEvent()
+	return scope.ApplyToEvent(event, nil).Tags
+}
+
 func (m *mockClient) getMessages() []string {
 	m.Lock()
 	defer m.Unlock()
@@ -36,6 +78,26 @@ func (m *mockClient) getMessages() []string {
 	return messages
 }
 
+// blockOnceClient wraps mockClient, blocking the first CaptureMessage call
+// until release is closed (started is closed just before blocking, so a
+// test can wait for the worker to actually be mid-flight). This lets a test
+// deterministically fill an async queue past capacity, instead of racing
+// the worker that drains it.
+type blockOnceClient struct {
+	mockClient
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockOnceClient) CaptureMessage(message string, hint *sentry.EventHint, scope sentry.EventModifier) *sentry.EventID {
+	m.once.Do(func() {
+		close(m.started)
+		<-m.release
+	})
+	return m.mockClient.CaptureMessage(message, hint, scope)
+}
+
 func TestSentryWriterWrite(t *testing.T) {
 	client := &mockClient{}
 	writer := sentrywriter.New(sentrywriter.LogLevel{"fatal", sentry.LevelFatal}).WithClient(client).WithUserID("userID").
@@ -151,3 +213,516 @@ func TestSentryWriterNoFilterByDefault(t *testing.T) {
 		t.Fatal("expected an error")
 	}
 }
+
+func TestSentryWriterWithContextIsolatesTags(t *testing.T) {
+	client := &mockClient{}
+	baseWriter := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client)
+
+	log := `{"level":"error","message":"blah"}`
+
+	var wg sync.WaitGroup
+	for i, requestID := range []string{"req-1", "req-2", "req-3"} {
+		wg.Add(1)
+		go func(i int, requestID string) {
+			defer wg.Done()
+
+			requestWriter := baseWriter.WithContext(context.Background()).WithTag("requestID", requestID)
+			if _, err := requestWriter.Write([]byte(log)); err != nil {
+				t.Errorf("writer.Write: %v", err)
+			}
+		}(i, requestID)
+	}
+	wg.Wait()
+
+	scopes := client.getScopes()
+	if len(scopes) != 3 {
+		t.Fatalf("Expected 3 messages, found: %d", len(scopes))
+	}
+
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		requestID := tagsFromScope(scope)["requestID"]
+		if requestID == "" {
+			t.Fatalf("expected a requestID tag to be set on the scope")
+		}
+		if seen[requestID] {
+			t.Fatalf("requestID %q was seen more than once, tags bled across requests", requestID)
+		}
+		seen[requestID] = true
+	}
+}
+
+func TestSentryWriterDynamicAndStaticTags(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithDynamicTags("traceID", "entityName").WithStaticTags(map[string]string{"service": "billing"})
+
+	log := `{"level":"error","message":"blah","traceID":"abc-123","entityName":42}`
+
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	tags := tagsFromScope(scopes[0])
+	assert.Equal(t, "abc-123", tags["traceID"])
+	assert.Equal(t, "42", tags["entityName"])
+	assert.Equal(t, "billing", tags["service"])
+}
+
+func TestSentryWriterDynamicTagsWithBreadcrumbFilteredLogs(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithBreadcrumbs(20).WithDynamicTags("traceID")
+
+	// Filtered out, so it should become a breadcrumb rather than an event,
+	// and must not be affected by the configured dynamic tags.
+	infoLog := `{"level":"info","message":"blah","traceID":"abc-123"}`
+	if _, err := writer.Write([]byte(infoLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+	if len(client.getMessages()) != 0 {
+		t.Fatalf("expected the info log to be filtered out, not sent")
+	}
+
+	errorLog := `{"level":"error","message":"blah","traceID":"def-456"}`
+	if _, err := writer.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+	tags := tagsFromScope(scopes[0])
+	assert.Equal(t, "def-456", tags["traceID"])
+}
+
+func TestSentryWriterBreadcrumbFieldMapping(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).
+		WithClient(client).WithBreadcrumbs(20)
+
+	infoLog := `{"level":"info","message":"query executed","sentry:category":"postgres","sentry:type":"query","time":"2021-01-02T15:04:05Z","table":"users"}`
+	if _, err := writer.Write([]byte(infoLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	errorLog := `{"level":"error","message":"boom"}`
+	if _, err := writer.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	event := sentry.NewEvent()
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 1 {
+		t.Fatalf("Expected 1 breadcrumb, found: %d", len(event.Breadcrumbs))
+	}
+
+	breadcrumb := event.Breadcrumbs[0]
+	assert.Equal(t, "postgres", breadcrumb.Category)
+	assert.Equal(t, "query", breadcrumb.Type)
+	assert.Equal(t, "query executed", breadcrumb.Message)
+	assert.Equal(t, sentry.LevelInfo, breadcrumb.Level)
+	assert.Equal(t, "2021-01-02T15:04:05Z", breadcrumb.Timestamp.Format(time.RFC3339))
+	assert.Equal(t, "users", breadcrumb.Data["table"])
+	if _, found := breadcrumb.Data["sentry:category"]; found {
+		t.Fatal("expected sentry:category to be lifted out of Data")
+	}
+}
+
+func TestSentryWriterCustomBreadcrumbFieldMapping(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).
+		WithClient(client).WithBreadcrumbs(20).
+		WithBreadcrumbFieldMapping(sentrywriter.BreadcrumbFieldMapping{
+			CategoryField: "category",
+			TypeField:     "type",
+			MessageFields: []string{"msg"},
+			TimeFields:    []string{"ts"},
+		})
+
+	infoLog := `{"level":"info","msg":"cache miss","category":"redis","type":"get","ts":1609599845}`
+	if _, err := writer.Write([]byte(infoLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	errorLog := `{"level":"error","message":"boom"}`
+	if _, err := writer.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	event := sentry.NewEvent()
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 1 {
+		t.Fatalf("Expected 1 breadcrumb, found: %d", len(event.Breadcrumbs))
+	}
+
+	breadcrumb := event.Breadcrumbs[0]
+	assert.Equal(t, "redis", breadcrumb.Category)
+	assert.Equal(t, "get", breadcrumb.Type)
+	assert.Equal(t, "cache miss", breadcrumb.Message)
+	assert.Equal(t, int64(1609599845), breadcrumb.Timestamp.Unix())
+}
+
+func TestSentryWriterSinkDoesNotLeakBreadcrumbsAcrossRequests(t *testing.T) {
+	client := &mockClient{}
+	baseWriter := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithBreadcrumbs(20)
+
+	ctxA := sentrywriter.NewSinkContext(context.Background())
+	writerA := baseWriter.WithContext(ctxA)
+
+	ctxB := sentrywriter.NewSinkContext(context.Background())
+	writerB := baseWriter.WithContext(ctxB)
+
+	if _, err := writerA.Write([]byte(`{"level":"info","message":"request A breadcrumb"}`)); err != nil {
+		t.Fatalf("writerA.Write: %v", err)
+	}
+
+	errorLog := `{"level":"error","message":"boom"}`
+	if _, err := writerB.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writerB.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	event := sentry.NewEvent()
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 0 {
+		t.Fatalf("expected request A's sunk breadcrumb not to leak onto request B's event, found %d breadcrumbs", len(event.Breadcrumbs))
+	}
+}
+
+func TestSentryWriterSinkFlushesOnMatchingContext(t *testing.T) {
+	client := &mockClient{}
+	baseWriter := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithBreadcrumbs(20)
+
+	ctx := sentrywriter.NewSinkContext(context.Background())
+	writer := baseWriter.WithContext(ctx)
+
+	if _, err := writer.Write([]byte(`{"level":"info","message":"on the way to an error"}`)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	errorLog := `{"level":"error","message":"boom"}`
+	if _, err := writer.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	event := sentry.NewEvent()
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 1 {
+		t.Fatalf("Expected 1 breadcrumb flushed from the sink, found: %d", len(event.Breadcrumbs))
+	}
+}
+
+func TestSentryWriterSinkEvictsOldestEntriesOnceBreadcrumbsLimitExceeded(t *testing.T) {
+	client := &mockClient{}
+	baseWriter := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithBreadcrumbs(2)
+
+	ctx := sentrywriter.NewSinkContext(context.Background())
+	writer := baseWriter.WithContext(ctx)
+
+	for i := 0; i < 5; i++ {
+		log := fmt.Sprintf(`{"level":"info","message":"info %d"}`, i)
+		if _, err := writer.Write([]byte(log)); err != nil {
+			t.Fatalf("writer.Write: %v", err)
+		}
+	}
+
+	errorLog := `{"level":"error","message":"boom"}`
+	if _, err := writer.Write([]byte(errorLog)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	scopes := client.getScopes()
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 message, found: %d", len(scopes))
+	}
+
+	event := sentry.NewEvent()
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 2 {
+		t.Fatalf("Expected the sink to have been capped at 2 breadcrumbs, found: %d", len(event.Breadcrumbs))
+	}
+	assert.Equal(t, "info 3", event.Breadcrumbs[0].Message)
+	assert.Equal(t, "info 4", event.Breadcrumbs[1].Message)
+}
+
+func TestSentryWriterWithExceptionExtraction(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithExceptionExtraction("error")
+
+	log := `{"level":"error","message":"blah","error":"connection refused"}`
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	if len(client.getMessages()) != 0 {
+		t.Fatal("expected the log to be sent via CaptureEvent, not CaptureMessage")
+	}
+
+	events := client.getEvents()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, found: %d", len(events))
+	}
+	if len(events[0].Exception) != 1 {
+		t.Fatalf("Expected 1 exception, found: %d", len(events[0].Exception))
+	}
+	assert.Equal(t, "connection refused", events[0].Exception[0].Value)
+}
+
+func TestSentryWriterWithStackTraceField(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithExceptionExtraction("error").WithStackTraceField("stack")
+
+	log := `{"level":"error","message":"blah","error":"boom","stack":[` +
+		`{"func":"main.inner","source":"/app/main.go","line":"20"},` +
+		`{"func":"main.outer","source":"/app/main.go","line":"10"}]}`
+
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	events := client.getEvents()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, found: %d", len(events))
+	}
+	stacktrace := events[0].Exception[0].Stacktrace
+	if stacktrace == nil {
+		t.Fatal("expected a parsed stacktrace")
+	}
+	if len(stacktrace.Frames) != 2 {
+		t.Fatalf("Expected 2 frames, found: %d", len(stacktrace.Frames))
+	}
+	// Sentry expects oldest-first ordering, i.e. main.outer (the caller)
+	// before main.inner (the callee where the error originated).
+	assert.Equal(t, "main.outer", stacktrace.Frames[0].Function)
+	assert.Equal(t, "main.inner", stacktrace.Frames[1].Function)
+}
+
+func TestSentryWriterWithStackTraceFieldParsesPkgErrorsStackTrace(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithExceptionExtraction("error").WithStackTraceField("stack")
+
+	err := errors.New("boom")
+	tracer, ok := err.(interface{ StackTrace() errors.StackTrace })
+	if !ok {
+		t.Fatal("expected a pkg/errors error to implement StackTrace()")
+	}
+	stack := fmt.Sprintf("%+v", tracer.StackTrace())
+
+	payload, marshalErr := json.Marshal(map[string]string{
+		"level":   "error",
+		"message": "blah",
+		"error":   err.Error(),
+		"stack":   stack,
+	})
+	if marshalErr != nil {
+		t.Fatalf("json.Marshal: %v", marshalErr)
+	}
+
+	if _, writeErr := writer.Write(payload); writeErr != nil {
+		t.Fatalf("writer.Write: %v", writeErr)
+	}
+
+	events := client.getEvents()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, found: %d", len(events))
+	}
+	stacktrace := events[0].Exception[0].Stacktrace
+	if stacktrace == nil || len(stacktrace.Frames) == 0 {
+		t.Fatal("expected a parsed stacktrace with at least one frame")
+	}
+
+	// Frames are oldest-first, so the innermost frame (this test function,
+	// where errors.New was called) is last.
+	innermost := stacktrace.Frames[len(stacktrace.Frames)-1]
+	if !strings.Contains(innermost.Function, "TestSentryWriterWithStackTraceFieldParsesPkgErrorsStackTrace") {
+		t.Fatalf("expected innermost frame to be this test function, got: %q", innermost.Function)
+	}
+	if innermost.Lineno == 0 {
+		t.Fatal("expected a non-zero line number")
+	}
+}
+
+func TestSentryWriterExceptionEventDoesNotDuplicateBreadcrumbs(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithExceptionExtraction("error").WithBreadcrumbs(20)
+
+	if _, err := writer.Write([]byte(`{"level":"info","message":"connecting"}`)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	log := `{"level":"error","message":"blah","error":"connection refused"}`
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	events := client.getEvents()
+	scopes := client.getScopes()
+	if len(events) != 1 || len(scopes) != 1 {
+		t.Fatalf("Expected 1 event and 1 scope, found: %d events, %d scopes", len(events), len(scopes))
+	}
+
+	// The real sentry-go client applies the scope to the event exactly once,
+	// inside CaptureEvent. Simulate that here; buildExceptionEvent must not
+	// have already applied it, or the breadcrumb below would be doubled.
+	event := events[0]
+	scopes[0].ApplyToEvent(event, nil)
+	if len(event.Breadcrumbs) != 1 {
+		t.Fatalf("Expected 1 breadcrumb, found: %d", len(event.Breadcrumbs))
+	}
+}
+
+func TestSentryWriterWithBeforeSendDiscardsEvent(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithExceptionExtraction("error").WithBeforeSend(func(event *sentry.Event) *sentry.Event {
+		return nil
+	})
+
+	log := `{"level":"error","message":"blah","error":"boom"}`
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	if len(client.getEvents()) != 0 {
+		t.Fatal("expected BeforeSend returning nil to discard the event")
+	}
+}
+
+func TestSentryWriterWithAsyncDeliversAllMessages(t *testing.T) {
+	client := &mockClient{}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithAsync(10, 2, sentrywriter.DropPolicyBlock)
+
+	log := `{"level":"error","message":"blah"}`
+	for i := 0; i < 5; i++ {
+		if _, err := writer.Write([]byte(log)); err != nil {
+			t.Fatalf("writer.Write: %v", err)
+		}
+	}
+
+	if !writer.Flush(time.Second) {
+		t.Fatal("expected Flush to complete before the timeout")
+	}
+
+	messages := client.getMessages()
+	if len(messages) != 5 {
+		t.Fatalf("Expected 5 messages, found: %d", len(messages))
+	}
+
+	stats := writer.Stats()
+	assert.Equal(t, int64(5), stats.Enqueued)
+	assert.Equal(t, int64(5), stats.Sent)
+	assert.Equal(t, int64(0), stats.Dropped)
+}
+
+func TestSentryWriterWithAsyncDropNewestWhenFull(t *testing.T) {
+	client := &mockClient{}
+	// No workers, so the queue never drains and the second write is dropped.
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithAsync(1, 0, sentrywriter.DropPolicyDropNewest)
+
+	log := `{"level":"error","message":"blah"}`
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+	if _, err := writer.Write([]byte(log)); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+
+	stats := writer.Stats()
+	assert.Equal(t, int64(1), stats.Enqueued)
+	assert.Equal(t, int64(1), stats.Dropped)
+}
+
+func TestSentryWriterWithAsyncDropOldestEvictsOldestWhenFull(t *testing.T) {
+	client := &blockOnceClient{started: make(chan struct{}), release: make(chan struct{})}
+	writer := sentrywriter.New(sentrywriter.LogLevel{"error", sentry.LevelError}).WithClient(client).
+		WithAsync(2, 1, sentrywriter.DropPolicyDropOldest)
+
+	msg := func(i int) string { return fmt.Sprintf(`{"level":"error","message":"msg %d"}`, i) }
+
+	if _, err := writer.Write([]byte(msg(0))); err != nil {
+		t.Fatalf("writer.Write: %v", err)
+	}
+	<-client.started // the worker has dequeued msg 0 and is blocked sending it, so the queue is empty again
+
+	// Fill the (now empty) capacity-2 queue past capacity: msg 1 and msg 2
+	// fit, then msg 3 forces DropPolicyDropOldest to evict the oldest
+	// queued entry (msg 1) to make room for itself.
+	for i := 1; i <= 3; i++ {
+		if _, err := writer.Write([]byte(msg(i))); err != nil {
+			t.Fatalf("writer.Write: %v", err)
+		}
+	}
+
+	close(client.release)
+
+	if !writer.Flush(time.Second) {
+		t.Fatal("expected Flush to complete before the timeout")
+	}
+
+	messages := client.getMessages()
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, found: %d", len(messages))
+	}
+	assert.Equal(t, msg(0), messages[0])
+	assert.Equal(t, msg(2), messages[1])
+	assert.Equal(t, msg(3), messages[2])
+
+	stats := writer.Stats()
+	assert.Equal(t, int64(4), stats.Enqueued)
+	assert.Equal(t, int64(1), stats.Dropped)
+}
+
+func TestSentryWriterWriterFromContext(t *testing.T) {
+	client := &mockClient{}
+	baseWriter := sentrywriter.New().WithClient(client)
+
+	requestWriter := baseWriter.WithContext(context.Background()).WithTag("requestID", "req-1")
+	ctx := requestWriter.Context()
+
+	found, ok := sentrywriter.WriterFromContext(ctx)
+	if !ok {
+		t.Fatal("expected to find a writer on the context")
+	}
+	if found != requestWriter {
+		t.Fatal("expected WriterFromContext to return the writer bound by WithContext")
+	}
+
+	if _, ok := sentrywriter.WriterFromContext(context.Background()); ok {
+		t.Fatal("expected no writer to be found on an unrelated context")
+	}
+}